@@ -0,0 +1,52 @@
+// Package auth issues and verifies the JWTs that gate POST /matches, so
+// only an authenticated user can submit a match result.
+package auth
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// tokenTTL is how long an issued token remains valid.
+const tokenTTL = 24 * time.Hour
+
+// secret signs and verifies tokens. Set JWT_SECRET in production; the
+// fallback is a fixed string compiled into the binary, shared by every
+// run and instance, so anyone with the source can sign valid tokens.
+var secret = secretFromEnv()
+
+func secretFromEnv() []byte {
+	if s := os.Getenv("JWT_SECRET"); s != "" {
+		return []byte(s)
+	}
+	return []byte("dev-secret-change-me")
+}
+
+// IssueToken returns a signed JWT asserting username as its subject.
+func IssueToken(username string) (string, error) {
+	claims := jwt.RegisteredClaims{
+		Subject:   username,
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenTTL)),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+}
+
+// VerifyToken validates a bearer token and returns the username it asserts.
+func VerifyToken(tokenString string) (string, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(t *jwt.Token) (interface{}, error) {
+		return secret, nil
+	})
+	if err != nil || !token.Valid {
+		return "", errors.New("invalid or expired token")
+	}
+
+	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	if !ok || claims.Subject == "" {
+		return "", errors.New("invalid token claims")
+	}
+	return claims.Subject, nil
+}
@@ -0,0 +1,24 @@
+package auth
+
+import "testing"
+
+func TestIssueAndVerifyToken(t *testing.T) {
+	token, err := IssueToken("rahul_1")
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+
+	username, err := VerifyToken(token)
+	if err != nil {
+		t.Fatalf("VerifyToken() error = %v", err)
+	}
+	if username != "rahul_1" {
+		t.Errorf("VerifyToken() = %q, want %q", username, "rahul_1")
+	}
+}
+
+func TestVerifyTokenRejectsGarbage(t *testing.T) {
+	if _, err := VerifyToken("not-a-token"); err == nil {
+		t.Error("VerifyToken(garbage) = nil error, want error")
+	}
+}
@@ -0,0 +1,105 @@
+// Package hub fans out leaderboard change events to SSE/WebSocket
+// subscribers over per-subscriber bounded buffers, dropping events for
+// slow consumers instead of letting them block a publish.
+package hub
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Event is a single leaderboard change pushed to subscribers: either a
+// "diff" (one user's rank/rating changed) or a "snapshot" (periodic
+// full-state heartbeat).
+type Event struct {
+	Type      string          `json:"type"`
+	Username  string          `json:"username,omitempty"`
+	OldRank   int             `json:"old_rank,omitempty"`
+	NewRank   int             `json:"new_rank,omitempty"`
+	OldRating int             `json:"old_rating,omitempty"`
+	NewRating int             `json:"new_rating,omitempty"`
+	Users     json.RawMessage `json:"users,omitempty"`
+}
+
+// Filter decides whether an event should be delivered to a given
+// subscriber, e.g. a "top:100" subscription that only cares about
+// changes touching the top 100 of the leaderboard.
+type Filter func(Event) bool
+
+// TopFilter builds a Filter that only passes diff events touching ranks
+// 1..n on either side of the change. Snapshot heartbeats always pass.
+func TopFilter(n int) Filter {
+	return func(e Event) bool {
+		if e.Type != "diff" {
+			return true
+		}
+		return e.OldRank <= n || e.NewRank <= n
+	}
+}
+
+// subscriberBuffer bounds how many unread events a subscriber can queue
+// before it is considered slow and further events are dropped for it.
+const subscriberBuffer = 32
+
+// Subscriber is a single client's bounded event queue.
+type Subscriber struct {
+	events chan Event
+	filter Filter
+}
+
+// Events returns the channel of events delivered to this subscriber. It
+// is closed once Unsubscribe is called.
+func (s *Subscriber) Events() <-chan Event {
+	return s.events
+}
+
+// Hub fans out published events to every active subscriber.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[*Subscriber]struct{}
+}
+
+// New returns an empty Hub.
+func New() *Hub {
+	return &Hub{subscribers: make(map[*Subscriber]struct{})}
+}
+
+// Subscribe registers a new subscriber. filter may be nil to receive
+// every event. Callers must call Unsubscribe once the client disconnects.
+func (h *Hub) Subscribe(filter Filter) *Subscriber {
+	sub := &Subscriber{events: make(chan Event, subscriberBuffer), filter: filter}
+
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub
+}
+
+// Unsubscribe removes sub and closes its event channel.
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	delete(h.subscribers, sub)
+	h.mu.Unlock()
+
+	close(sub.events)
+}
+
+// Publish fans event out to every subscriber whose filter accepts it. A
+// subscriber whose buffer is already full is skipped for this event
+// rather than blocking the publisher.
+func (h *Hub) Publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			// Slow consumer: drop this event rather than block the publisher.
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package hub
+
+import "testing"
+
+func TestPublishDropsForSlowSubscriber(t *testing.T) {
+	h := New()
+	sub := h.Subscribe(nil)
+
+	for i := 0; i < subscriberBuffer+5; i++ {
+		h.Publish(Event{Type: "diff", Username: "u"})
+	}
+
+	if got := len(sub.events); got != subscriberBuffer {
+		t.Errorf("len(sub.events) = %d, want %d (buffer should cap, excess dropped)", got, subscriberBuffer)
+	}
+}
+
+func TestTopFilter(t *testing.T) {
+	filter := TopFilter(100)
+
+	cases := []struct {
+		event Event
+		want  bool
+	}{
+		{Event{Type: "diff", OldRank: 50, NewRank: 60}, true},
+		{Event{Type: "diff", OldRank: 150, NewRank: 200}, false},
+		{Event{Type: "diff", OldRank: 200, NewRank: 90}, true},
+		{Event{Type: "snapshot"}, true},
+	}
+
+	for _, c := range cases {
+		if got := filter(c.event); got != c.want {
+			t.Errorf("TopFilter(100)(%+v) = %v, want %v", c.event, got, c.want)
+		}
+	}
+}
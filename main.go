@@ -1,162 +1,885 @@
-package main
-
-import (
-	"fmt"
-	"math/rand"
-	"os"
-	"sort"
-	"strings"
-	"sync"
-	"time"
-
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
-)
-
-// User represents a player in the system
-type User struct {
-	Username string `json:"username"`
-	Rating   int    `json:"rating"`
-	Rank     int    `json:"rank"`
-}
-
-// LeaderboardSystem holds all data in memory for speed
-type LeaderboardSystem struct {
-	sync.RWMutex
-	Users   []*User          // Sorted list for Leaderboard display
-	UserMap map[string]*User // Hash map for O(1) lookups
-}
-
-var sys = &LeaderboardSystem{
-	UserMap: make(map[string]*User),
-}
-
-// RecalculateRanks implements the "Tie-Aware Ranking" requirement [cite: 12, 47]
-// Users with the same rating get the same rank.
-func (ls *LeaderboardSystem) RecalculateRanks() {
-	ls.Lock()
-	defer ls.Unlock()
-
-	// Sort Descending by Rating
-	sort.Slice(ls.Users, func(i, j int) bool {
-		return ls.Users[i].Rating > ls.Users[j].Rating
-	})
-
-	// Assign Ranks
-	currentRank := 1
-	for i, user := range ls.Users {
-		// If not first user and rating is different from previous, update rank
-		if i > 0 && user.Rating < ls.Users[i-1].Rating {
-			currentRank = i + 1
-		}
-		user.Rank = currentRank
-	}
-}
-
-// SeedUsers generates 10,000 users as required [cite: 25]
-func SeedUsers() {
-	sys.Lock()
-	defer sys.Unlock()
-
-	names := []string{"rahul", "arjun", "priya", "vikram", "anisha", "rohan", "sara", "kabir"}
-
-	for i := 0; i < 10000; i++ {
-		baseName := names[rand.Intn(len(names))]
-		username := fmt.Sprintf("%s_%d", baseName, i)
-		// Rating between 100 and 5000 [cite: 45]
-		rating := rand.Intn(4901) + 100 
-
-		newUser := &User{Username: username, Rating: rating}
-		sys.Users = append(sys.Users, newUser)
-		sys.UserMap[username] = newUser
-	}
-	fmt.Println("✅ Seeded 10,000 users.")
-}
-
-// StartScoreUpdates simulates random updates every 10s [cite: 28, 57]
-func StartScoreUpdates() {
-	ticker := time.NewTicker(10 * time.Second)
-	go func() {
-		for range ticker.C {
-			sys.Lock()
-			// Update 50 random users to simulate activity
-			for k := 0; k < 50; k++ {
-				idx := rand.Intn(len(sys.Users))
-				sys.Users[idx].Rating = rand.Intn(4901) + 100
-			}
-			sys.Unlock()
-
-			sys.RecalculateRanks()
-			fmt.Println("🔄 Ratings updated and Ranks recalculated")
-		}
-	}()
-}
-
-func main() {
-	rand.Seed(time.Now().UnixNano())
-	SeedUsers()
-	sys.RecalculateRanks()
-	StartScoreUpdates()
-
-	r := gin.Default()
-	
-	// Enable CORS so the frontend can talk to backend
-	config := cors.DefaultConfig()
-	config.AllowAllOrigins = true
-	r.Use(cors.New(config))
-
-	// API: Get Leaderboard
-	r.GET("/leaderboard", func(c *gin.Context) {
-		sys.RLock()
-		defer sys.RUnlock()
-
-		// Return top 100 to keep UI snappy (Pagination logic)
-		limit := 100
-		if len(sys.Users) < limit {
-			limit = len(sys.Users)
-		}
-		c.JSON(200, sys.Users[:limit])
-	})
-
-	// API: Search User [cite: 54]
-	r.GET("/search", func(c *gin.Context) {
-		query := strings.ToLower(c.Query("username"))
-		if query == "" {
-			c.JSON(400, gin.H{"error": "Query required"})
-			return
-		}
-
-		var results []*User
-		sys.RLock()
-		defer sys.RUnlock()
-
-		// 1. Exact Match (O(1) Lookup)
-		if u, exists := sys.UserMap[query]; exists {
-			results = append(results, u)
-		}
-
-		// 2. Partial Match (Search first 10k users efficiently)
-		count := 0
-		for _, u := range sys.Users {
-			if strings.Contains(strings.ToLower(u.Username), query) && u.Username != query {
-				results = append(results, u)
-				count++
-			}
-			if count >= 10 { // Limit results to prevent lag
-				break 
-			}
-		}
-
-		c.JSON(200, results)
-	})
-
-	port := os.Getenv("PORT")
-if port == "" {
-	port = "8080" // fallback for local run
-}
-
-fmt.Printf("🚀 Server running on port %s\n", port)
-r.Run(":" + port)
-
-
-}
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fasthttp/router"
+	"github.com/fasthttp/websocket"
+	"github.com/valyala/fasthttp"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/uhavasantha/matiks-leaderboard-backend/auth"
+	"github.com/uhavasantha/matiks-leaderboard-backend/hub"
+	"github.com/uhavasantha/matiks-leaderboard-backend/ranktree"
+	"github.com/uhavasantha/matiks-leaderboard-backend/storage"
+	"github.com/uhavasantha/matiks-leaderboard-backend/trie"
+)
+
+// eventHub fans out leaderboard diffs and snapshot heartbeats to /stream
+// and /ws subscribers.
+var eventHub = hub.New()
+
+// H is a convenience alias for building JSON response bodies inline.
+type H map[string]interface{}
+
+// User represents a player in the system
+type User struct {
+	Username string `json:"username"`
+	Rating   int    `json:"rating"`
+	Rank     int    `json:"rank"`
+	index    int    // position in the owning view's users slice; ranks tie, slice indices don't
+}
+
+// leaderboardView is one immutable, fully-ranked snapshot of the
+// leaderboard. Readers take a pointer to a view and never block a
+// writer, and never see a partially-sorted slice.
+type leaderboardView struct {
+	users  []*User          // sorted desc by rating, ties share a rank
+	byName map[string]*User // same *User objects, keyed by username
+}
+
+// LeaderboardSystem holds all data in memory for speed
+type LeaderboardSystem struct {
+	mu          sync.Mutex        // guards ratings/credentials/tree/Trie; view readers never take it
+	ratings     map[string]int    // authoritative rating per user
+	credentials map[string]string // bcrypt password hash per username
+	tree        *ranktree.Tree    // order-statistic tree, kept in sync with ratings
+	Trie        *trie.Trie        // prefix/fuzzy index over usernames, for /search
+	current     atomic.Pointer[leaderboardView]
+	viewMu      sync.Mutex    // serializes RecalculateRanks so concurrent swaps can't store out of order
+	Store       storage.Store // persists ratings so a restart doesn't wipe them
+}
+
+var sys = &LeaderboardSystem{
+	ratings:     make(map[string]int),
+	credentials: make(map[string]string),
+	tree:        ranktree.New(),
+	Trie:        trie.New(),
+}
+
+// startingRating is the Elo rating a newly registered user begins at.
+const startingRating = 1000
+
+// ErrUsernameTaken is the sentinel wrapped into Register's error when the
+// username is already registered, so callers can tell it apart from a
+// bcrypt failure without parsing the error string.
+var ErrUsernameTaken = errors.New("username already taken")
+
+// Register creates a new user with a bcrypt-hashed password and an
+// initial rating, so they can start submitting match results via
+// /matches. It returns an error wrapping ErrUsernameTaken if the username
+// is already taken, or a plain wrapped error if hashing the password fails.
+func (ls *LeaderboardSystem) Register(username, password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+
+	ls.mu.Lock()
+	if _, exists := ls.ratings[username]; exists {
+		ls.mu.Unlock()
+		return fmt.Errorf("%w: %q", ErrUsernameTaken, username)
+	}
+	ls.credentials[username] = string(hash)
+	ls.ratings[username] = startingRating
+	ls.tree.Insert(ranktree.Item{Username: username, Rating: startingRating})
+	ls.Trie.Insert(username)
+	ls.mu.Unlock()
+
+	if ls.Store != nil {
+		if err := ls.Store.Save(storage.Record{Username: username, Rating: startingRating}); err != nil {
+			fmt.Printf("⚠️  failed to persist new user %s: %v\n", username, err)
+		}
+	}
+	return nil
+}
+
+// Rating returns a user's current rating, for building Elo updates.
+func (ls *LeaderboardSystem) Rating(username string) (int, bool) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	rating, exists := ls.ratings[username]
+	return rating, exists
+}
+
+// ErrUserNotFound is returned by RecordMatch when winner or loser isn't
+// a registered user.
+var ErrUserNotFound = errors.New("user not found")
+
+// RecordMatch applies one match's Elo update for both players, holding
+// ls.mu across the rating read, the Elo computation, and both writes so
+// two concurrent matches touching the same player can't both read the
+// pre-update rating and have the later write silently clobber the
+// earlier one.
+func (ls *LeaderboardSystem) RecordMatch(winner, loser string, winnerScore float64) (oldWinnerRating, newWinnerRating, oldLoserRating, newLoserRating int, err error) {
+	ls.mu.Lock()
+	winnerRating, exists := ls.ratings[winner]
+	if !exists {
+		ls.mu.Unlock()
+		return 0, 0, 0, 0, fmt.Errorf("%w: %q", ErrUserNotFound, winner)
+	}
+	loserRating, exists := ls.ratings[loser]
+	if !exists {
+		ls.mu.Unlock()
+		return 0, 0, 0, 0, fmt.Errorf("%w: %q", ErrUserNotFound, loser)
+	}
+
+	newWinnerRating, newLoserRating = updateElo(winnerRating, loserRating, winnerScore)
+	ls.setRatingLocked(winner, newWinnerRating)
+	ls.setRatingLocked(loser, newLoserRating)
+	ls.mu.Unlock()
+
+	if ls.Store != nil {
+		if err := ls.Store.Save(storage.Record{Username: winner, Rating: newWinnerRating}); err != nil {
+			fmt.Printf("⚠️  failed to persist rating for %s: %v\n", winner, err)
+		}
+		if err := ls.Store.Save(storage.Record{Username: loser, Rating: newLoserRating}); err != nil {
+			fmt.Printf("⚠️  failed to persist rating for %s: %v\n", loser, err)
+		}
+	}
+
+	return winnerRating, newWinnerRating, loserRating, newLoserRating, nil
+}
+
+func init() {
+	sys.current.Store(&leaderboardView{byName: make(map[string]*User)})
+}
+
+// View returns the current lock-free read snapshot. It is always
+// non-nil, though it may be empty before the system is seeded.
+func (ls *LeaderboardSystem) View() *leaderboardView {
+	return ls.current.Load()
+}
+
+// setRatingLocked upserts a user's rating in the order-statistic tree
+// (delete the old node, insert the new one - each O(log N)). Callers must
+// hold ls.mu.
+func (ls *LeaderboardSystem) setRatingLocked(username string, rating int) {
+	if oldRating, exists := ls.ratings[username]; exists {
+		ls.tree.Delete(ranktree.Item{Username: username, Rating: oldRating})
+	}
+	ls.ratings[username] = rating
+	ls.tree.Insert(ranktree.Item{Username: username, Rating: rating})
+}
+
+// setRating upserts a user's rating and point-writes it to the store so
+// a crash loses at most the in-flight request. The change isn't visible
+// to readers of the current view (page-based /leaderboard, /around,
+// /search) until the next RecalculateRanks swap, but GetRank/GetRange
+// (and so from_rank/to_rank queries) see it immediately.
+func (ls *LeaderboardSystem) setRating(username string, rating int) {
+	ls.mu.Lock()
+	ls.setRatingLocked(username, rating)
+	ls.mu.Unlock()
+
+	if ls.Store != nil {
+		if err := ls.Store.Save(storage.Record{Username: username, Rating: rating}); err != nil {
+			fmt.Printf("⚠️  failed to persist rating for %s: %v\n", username, err)
+		}
+	}
+}
+
+// GetRank returns a user's current tie-aware rank in O(log N), reading
+// straight from the order-statistic tree instead of waiting for the next
+// full RecalculateRanks sweep.
+func (ls *LeaderboardSystem) GetRank(username string) (int, bool) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	rating, exists := ls.ratings[username]
+	if !exists {
+		return 0, false
+	}
+	return ls.tree.RankOf(rating), true
+}
+
+// GetRange returns up to count users starting at the 1-based fromRank in
+// O(log N + count), by walking only the subtrees that overlap the
+// requested window rather than slicing a pre-sorted array.
+func (ls *LeaderboardSystem) GetRange(fromRank, count int) []*User {
+	ls.mu.Lock()
+	items := ls.tree.Range(fromRank, count)
+	var startRank int
+	if len(items) > 0 {
+		startRank = ls.tree.RankOf(items[0].Rating)
+	}
+	ls.mu.Unlock()
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	users := make([]*User, len(items))
+	rank := startRank
+	for i, item := range items {
+		if i > 0 && item.Rating < items[i-1].Rating {
+			rank = fromRank + i
+		}
+		users[i] = &User{Username: item.Username, Rating: item.Rating, Rank: rank}
+	}
+	return users
+}
+
+// FlushToStore force-flushes every user's current rating to the store.
+// Used by the periodic snapshot ticker and the /admin/snapshot endpoint.
+func (ls *LeaderboardSystem) FlushToStore() error {
+	ls.mu.Lock()
+	recs := make([]storage.Record, 0, len(ls.ratings))
+	for username, rating := range ls.ratings {
+		recs = append(recs, storage.Record{Username: username, Rating: rating})
+	}
+	ls.mu.Unlock()
+
+	if ls.Store == nil {
+		return nil
+	}
+	return ls.Store.SaveAll(recs)
+}
+
+// RecalculateRanks implements the "Tie-Aware Ranking" requirement [cite: 12, 47].
+// Users with the same rating get the same rank. The tree is already kept
+// sorted incrementally by setRating, so this is an O(N) in-order walk
+// rather than an O(N log N) re-sort; it builds a brand new []*User off
+// to the side and swaps it into ls.current atomically, so /leaderboard
+// and /search readers never block and never observe a half-built view.
+// Concurrent callers are serialized via viewMu so a staler snapshot can't
+// finish building after a fresher one and clobber it in ls.current.
+func (ls *LeaderboardSystem) RecalculateRanks() {
+	// Serializes the whole snapshot -> build -> store sequence so two
+	// concurrent callers can't race: without this, whichever goroutine
+	// snapshotted the tree earlier could still finish building and
+	// Store after the other, silently clobbering the fresher view.
+	ls.viewMu.Lock()
+	defer ls.viewMu.Unlock()
+
+	oldView := ls.current.Load()
+
+	ls.mu.Lock()
+	items := ls.tree.InOrder()
+	ls.mu.Unlock()
+
+	users := make([]*User, len(items))
+	byName := make(map[string]*User, len(items))
+	currentRank := 1
+	for i, item := range items {
+		// If not first user and rating is different from previous, update rank
+		if i > 0 && item.Rating < items[i-1].Rating {
+			currentRank = i + 1
+		}
+		u := &User{Username: item.Username, Rating: item.Rating, Rank: currentRank, index: i}
+		users[i] = u
+		byName[item.Username] = u
+	}
+
+	newView := &leaderboardView{users: users, byName: byName}
+	ls.current.Store(newView)
+
+	publishDiffs(oldView, newView)
+}
+
+// publishDiffs compares two consecutive views and pushes a diff event
+// for every user whose rank or rating changed, so /stream and /ws
+// subscribers don't have to poll /leaderboard.
+func publishDiffs(oldView, newView *leaderboardView) {
+	if oldView == nil || len(oldView.byName) == 0 {
+		return // nothing to diff against yet (first build after boot)
+	}
+
+	for username, newUser := range newView.byName {
+		oldUser, existed := oldView.byName[username]
+		if !existed || (oldUser.Rank == newUser.Rank && oldUser.Rating == newUser.Rating) {
+			continue
+		}
+		eventHub.Publish(hub.Event{
+			Type:      "diff",
+			Username:  username,
+			OldRank:   oldUser.Rank,
+			NewRank:   newUser.Rank,
+			OldRating: oldUser.Rating,
+			NewRating: newUser.Rating,
+		})
+	}
+}
+
+// SeedUsers generates 10,000 users as required [cite: 25]
+func SeedUsers() {
+	sys.mu.Lock()
+	names := []string{"rahul", "arjun", "priya", "vikram", "anisha", "rohan", "sara", "kabir"}
+	recs := make([]storage.Record, 0, 10000)
+
+	for i := 0; i < 10000; i++ {
+		baseName := names[rand.Intn(len(names))]
+		username := fmt.Sprintf("%s_%d", baseName, i)
+		// Rating between 100 and 5000 [cite: 45]
+		rating := rand.Intn(4901) + 100
+
+		sys.ratings[username] = rating
+		sys.tree.Insert(ranktree.Item{Username: username, Rating: rating})
+		sys.Trie.Insert(username)
+		recs = append(recs, storage.Record{Username: username, Rating: rating})
+	}
+	sys.mu.Unlock()
+	fmt.Println("✅ Seeded 10,000 users.")
+
+	if sys.Store != nil {
+		if err := sys.Store.SaveAll(recs); err != nil {
+			fmt.Printf("⚠️  failed to persist seeded users: %v\n", err)
+		}
+	}
+}
+
+// Rehydrate loads any previously persisted users from the store so a
+// restart picks up where the last run left off. It reports whether any
+// records were found.
+func Rehydrate() (bool, error) {
+	recs, err := sys.Store.Load()
+	if err != nil {
+		return false, fmt.Errorf("rehydrate: %w", err)
+	}
+	if len(recs) == 0 {
+		return false, nil
+	}
+
+	sys.mu.Lock()
+	for _, rec := range recs {
+		sys.ratings[rec.Username] = rec.Rating
+		sys.tree.Insert(ranktree.Item{Username: rec.Username, Rating: rec.Rating})
+		sys.Trie.Insert(rec.Username)
+	}
+	sys.mu.Unlock()
+
+	fmt.Printf("✅ Rehydrated %d users from store.\n", len(recs))
+	return true, nil
+}
+
+// StartSnapshotTicker periodically force-flushes the full leaderboard to
+// the store, independent of the per-write point saves, as a safety net
+// against missed writes.
+func StartSnapshotTicker() {
+	ticker := time.NewTicker(60 * time.Second)
+	go func() {
+		for range ticker.C {
+			if err := sys.FlushToStore(); err != nil {
+				fmt.Printf("⚠️  snapshot failed: %v\n", err)
+				continue
+			}
+			fmt.Println("💾 Snapshot flushed to store")
+		}
+	}()
+}
+
+// StartHeartbeatTicker periodically publishes a full-snapshot event of
+// the top 100 users, so /stream and /ws subscribers can resync even if
+// they missed individual diffs.
+func StartHeartbeatTicker() {
+	ticker := time.NewTicker(30 * time.Second)
+	go func() {
+		for range ticker.C {
+			view := sys.View()
+			limit := 100
+			if len(view.users) < limit {
+				limit = len(view.users)
+			}
+
+			data, err := json.Marshal(view.users[:limit])
+			if err != nil {
+				fmt.Printf("⚠️  failed to encode heartbeat snapshot: %v\n", err)
+				continue
+			}
+			eventHub.Publish(hub.Event{Type: "snapshot", Users: data})
+		}
+	}()
+}
+
+// StartScoreUpdates simulates random updates every 10s [cite: 28, 57].
+// Real rating changes now come from POST /matches, so this only runs in
+// SEED_MODE for demos without live traffic.
+func StartScoreUpdates() {
+	ticker := time.NewTicker(10 * time.Second)
+	go func() {
+		for range ticker.C {
+			view := sys.View()
+			if len(view.users) == 0 {
+				continue
+			}
+			// Update 50 random users to simulate activity
+			for k := 0; k < 50; k++ {
+				u := view.users[rand.Intn(len(view.users))]
+				sys.setRating(u.Username, rand.Intn(4901)+100)
+			}
+
+			sys.RecalculateRanks()
+			fmt.Println("🔄 Ratings updated and Ranks recalculated")
+		}
+	}()
+}
+
+// maxPageSize caps page_size so (page-1)*pageSize / page*pageSize can't
+// overflow int before clampRange gets a chance to bound them.
+const maxPageSize = 1000
+
+// clampRange clamps [start, end) to valid slice bounds for a total of
+// length n, so callers can slice a leaderboardView's users directly
+// without panicking on out-of-range pagination params.
+func clampRange(start, end, n int) (int, int) {
+	if start < 0 {
+		start = 0
+	}
+	if end > n {
+		end = n
+	}
+	if start > end {
+		start = end
+	}
+	return start, end
+}
+
+// writeJSON encodes payload as the response body and sets the status
+// code and content type accordingly.
+func writeJSON(ctx *fasthttp.RequestCtx, status int, payload interface{}) {
+	ctx.SetStatusCode(status)
+	ctx.SetContentType("application/json")
+	if err := json.NewEncoder(ctx).Encode(payload); err != nil {
+		fmt.Printf("⚠️  failed to encode response: %v\n", err)
+	}
+}
+
+// withCORS enables CORS so the frontend can talk to the backend, mirroring
+// the permissive dev-mode config the gin+gin-contrib/cors setup used.
+func withCORS(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.Header.Set("Access-Control-Allow-Origin", "*")
+		ctx.Response.Header.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		ctx.Response.Header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		if string(ctx.Method()) == fasthttp.MethodOptions {
+			ctx.SetStatusCode(fasthttp.StatusNoContent)
+			return
+		}
+		h(ctx)
+	}
+}
+
+// handleLeaderboard serves the paginated leaderboard, paginated via
+// page/page_size or an explicit from_rank/to_rank range.
+func handleLeaderboard(ctx *fasthttp.RequestCtx) {
+	view := sys.View()
+	total := len(view.users)
+
+	args := ctx.QueryArgs()
+	fromStr, toStr := string(args.Peek("from_rank")), string(args.Peek("to_rank"))
+	if fromStr != "" || toStr != "" {
+		fromRank, err := strconv.Atoi(fromStr)
+		if err != nil || fromRank < 1 {
+			writeJSON(ctx, 400, H{"error": "from_rank must be a positive integer"})
+			return
+		}
+		toRank, err := strconv.Atoi(toStr)
+		if err != nil || toRank < fromRank {
+			writeJSON(ctx, 400, H{"error": "to_rank must be an integer >= from_rank"})
+			return
+		}
+		if toRank > total {
+			toRank = total
+		}
+		count := toRank - fromRank + 1
+		if count < 0 {
+			count = 0
+		}
+
+		// Served straight from the order-statistic tree so it reflects
+		// writes that haven't made it into the next full view rebuild yet.
+		// toRank is clamped to total above so count can never ask Range
+		// to preallocate more than the actual user count.
+		writeJSON(ctx, 200, H{
+			"items":     sys.GetRange(fromRank, count),
+			"total":     total,
+			"from_rank": fromRank,
+			"to_rank":   toRank,
+		})
+		return
+	}
+
+	page, pageSize := 1, 100
+	if v, err := strconv.Atoi(string(args.Peek("page"))); err == nil && v > 0 {
+		page = v
+	}
+	if v, err := strconv.Atoi(string(args.Peek("page_size"))); err == nil && v > 0 {
+		pageSize = v
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	// A page beyond total+1 only ever yields an empty slice, so clamp it
+	// before multiplying by pageSize - an unclamped page near MaxInt
+	// would overflow (page-1)*pageSize into a value clampRange's
+	// post-hoc bounds check can't catch.
+	if maxPage := total + 1; page > maxPage {
+		page = maxPage
+	}
+
+	start, end := clampRange((page-1)*pageSize, page*pageSize, total)
+	var nextCursor *int
+	if end < total {
+		next := page + 1
+		nextCursor = &next
+	}
+
+	writeJSON(ctx, 200, H{
+		"items":       view.users[start:end],
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+		"next_cursor": nextCursor,
+	})
+}
+
+// handleLeaderboardAround serves the "my neighborhood" view: the window
+// users immediately above and below a given username.
+func handleLeaderboardAround(ctx *fasthttp.RequestCtx) {
+	username := strings.ToLower(fmt.Sprintf("%v", ctx.UserValue("username")))
+	window := 25
+	if v, err := strconv.Atoi(string(ctx.QueryArgs().Peek("window"))); err == nil && v > 0 {
+		window = v
+	}
+
+	view := sys.View()
+	u, exists := view.byName[username]
+	if !exists {
+		writeJSON(ctx, 404, H{"error": "User not found"})
+		return
+	}
+
+	idx := u.index // ranks tie (RecalculateRanks), so Rank-1 isn't a reliable slice index
+	total := len(view.users)
+	// A window >= total already covers the whole leaderboard, so clamp
+	// it to total before the idx-window/idx+window+1 arithmetic - an
+	// unclamped window near MaxInt would overflow into a value
+	// clampRange's post-hoc bounds check can't catch.
+	if window > total {
+		window = total
+	}
+	start, end := clampRange(idx-window, idx+window+1, total)
+
+	writeJSON(ctx, 200, H{
+		"items": view.users[start:end],
+		"total": total,
+	})
+}
+
+// maxSearchResults caps /search responses to keep typeahead payloads small.
+const maxSearchResults = 20
+
+// handleSearch implements Search User [cite: 54] via the username trie:
+// prefix lookup by default, or bounded fuzzy matching with ?fuzzy=1.
+func handleSearch(ctx *fasthttp.RequestCtx) {
+	args := ctx.QueryArgs()
+	term := string(args.Peek("prefix"))
+	if term == "" {
+		term = string(args.Peek("username"))
+	}
+	if term == "" {
+		writeJSON(ctx, 400, H{"error": "Query required"})
+		return
+	}
+
+	var usernames []string
+	if string(args.Peek("fuzzy")) == "1" {
+		usernames = sys.Trie.FuzzySearch(term, 2)
+	} else {
+		usernames = sys.Trie.PrefixSearch(term)
+	}
+
+	view := sys.View()
+	results := make([]*User, 0, len(usernames))
+	for _, username := range usernames {
+		if u, exists := view.byName[strings.ToLower(username)]; exists {
+			results = append(results, u)
+		}
+	}
+
+	// Rank ascending so the strongest leaderboard position comes first.
+	sort.Slice(results, func(i, j int) bool { return results[i].Rank < results[j].Rank })
+	if len(results) > maxSearchResults {
+		results = results[:maxSearchResults]
+	}
+
+	writeJSON(ctx, 200, results)
+}
+
+// wsUpgrader upgrades /ws connections. Origin checks are left to the
+// reverse proxy / CORS layer in front of this service.
+var wsUpgrader = websocket.FastHTTPUpgrader{
+	CheckOrigin: func(ctx *fasthttp.RequestCtx) bool { return true },
+}
+
+// parseFilter turns a ?filter= query value into a hub.Filter. Only
+// "top:N" is currently supported; anything else means no filtering.
+func parseFilter(raw string) hub.Filter {
+	if !strings.HasPrefix(raw, "top:") {
+		return nil
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(raw, "top:"))
+	if err != nil || n <= 0 {
+		return nil
+	}
+	return hub.TopFilter(n)
+}
+
+// handleStream serves GET /stream: a Server-Sent Events feed of diff
+// events plus the periodic full-snapshot heartbeat.
+func handleStream(ctx *fasthttp.RequestCtx) {
+	sub := eventHub.Subscribe(parseFilter(string(ctx.QueryArgs().Peek("filter"))))
+
+	ctx.SetContentType("text/event-stream")
+	ctx.Response.Header.Set("Cache-Control", "no-cache")
+	ctx.Response.Header.Set("Connection", "keep-alive")
+
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer eventHub.Unsubscribe(sub)
+
+		for event := range sub.Events() {
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+}
+
+// handleWS serves GET /ws: the same diff/heartbeat events as /stream,
+// pushed over a WebSocket connection instead.
+func handleWS(ctx *fasthttp.RequestCtx) {
+	sub := eventHub.Subscribe(parseFilter(string(ctx.QueryArgs().Peek("filter"))))
+
+	err := wsUpgrader.Upgrade(ctx, func(conn *websocket.Conn) {
+		defer eventHub.Unsubscribe(sub)
+		defer conn.Close()
+
+		for event := range sub.Events() {
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+	})
+	if err != nil {
+		fmt.Printf("⚠️  websocket upgrade failed: %v\n", err)
+	}
+}
+
+// eloKFactor controls how far a single match can move a rating. It is
+// configurable via ELO_K_FACTOR, defaulting to the conventional 32.
+var eloKFactor = eloKFactorFromEnv()
+
+func eloKFactorFromEnv() float64 {
+	if v, err := strconv.ParseFloat(os.Getenv("ELO_K_FACTOR"), 64); err == nil && v > 0 {
+		return v
+	}
+	return 32
+}
+
+// eloExpected returns the probability ratingA is expected to beat
+// ratingB under the standard Elo logistic model.
+func eloExpected(ratingA, ratingB int) float64 {
+	return 1 / (1 + math.Pow(10, float64(ratingB-ratingA)/400))
+}
+
+// updateElo returns the post-match ratings for a winner and loser given
+// the winner's actual score (1 for a decisive win, 0.5 for a draw).
+func updateElo(winnerRating, loserRating int, winnerScore float64) (newWinnerRating, newLoserRating int) {
+	expectedWinner := eloExpected(winnerRating, loserRating)
+	newWinnerRating = winnerRating + int(math.Round(eloKFactor*(winnerScore-expectedWinner)))
+	newLoserRating = loserRating + int(math.Round(eloKFactor*((1-winnerScore)-(1-expectedWinner))))
+	return newWinnerRating, newLoserRating
+}
+
+// requireAuth wraps a handler so it only runs for requests bearing a
+// valid JWT, making the asserted username available to h via
+// ctx.UserValue("username").
+func requireAuth(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		authHeader := string(ctx.Request.Header.Peek("Authorization"))
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			writeJSON(ctx, 401, H{"error": "missing bearer token"})
+			return
+		}
+
+		username, err := auth.VerifyToken(strings.TrimPrefix(authHeader, "Bearer "))
+		if err != nil {
+			writeJSON(ctx, 401, H{"error": "invalid or expired token"})
+			return
+		}
+
+		ctx.SetUserValue("username", username)
+		h(ctx)
+	}
+}
+
+// registerRequest is the POST /users body.
+type registerRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// handleRegister serves POST /users: registers a new user with a
+// bcrypt-hashed password and returns a JWT for immediate use.
+func handleRegister(ctx *fasthttp.RequestCtx) {
+	var req registerRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		writeJSON(ctx, 400, H{"error": "invalid request body"})
+		return
+	}
+
+	username := strings.ToLower(req.Username)
+	if username == "" || len(req.Password) < 8 {
+		writeJSON(ctx, 400, H{"error": "username is required and password must be at least 8 characters"})
+		return
+	}
+
+	if err := sys.Register(username, req.Password); err != nil {
+		status := 400
+		if errors.Is(err, ErrUsernameTaken) {
+			status = 409
+		}
+		writeJSON(ctx, status, H{"error": err.Error()})
+		return
+	}
+	sys.RecalculateRanks()
+
+	token, err := auth.IssueToken(username)
+	if err != nil {
+		writeJSON(ctx, 500, H{"error": "failed to issue token"})
+		return
+	}
+
+	writeJSON(ctx, 201, H{"username": username, "token": token})
+}
+
+// matchRequest is the POST /matches body. Score is the winner's actual
+// match outcome (1 for a decisive win, 0.5 for a draw) and defaults to 1
+// when omitted.
+type matchRequest struct {
+	Winner string   `json:"winner"`
+	Loser  string   `json:"loser"`
+	Score  *float64 `json:"score"`
+}
+
+// handleMatches serves POST /matches: updates both players' ratings via
+// the Elo formula. The caller (from their JWT) must be the winner or the
+// loser - no one can submit results on another player's behalf.
+func handleMatches(ctx *fasthttp.RequestCtx) {
+	var req matchRequest
+	if err := json.Unmarshal(ctx.PostBody(), &req); err != nil {
+		writeJSON(ctx, 400, H{"error": "invalid request body"})
+		return
+	}
+
+	winner := strings.ToLower(req.Winner)
+	loser := strings.ToLower(req.Loser)
+	if winner == "" || loser == "" || winner == loser {
+		writeJSON(ctx, 400, H{"error": "winner and loser must be distinct, non-empty usernames"})
+		return
+	}
+
+	authedUser, _ := ctx.UserValue("username").(string)
+	if authedUser != winner && authedUser != loser {
+		writeJSON(ctx, 403, H{"error": "you may only submit results for your own matches"})
+		return
+	}
+
+	score := 1.0
+	if req.Score != nil {
+		score = *req.Score
+	}
+	if score < 0 || score > 1 {
+		writeJSON(ctx, 400, H{"error": "score must be between 0 and 1"})
+		return
+	}
+
+	oldWinnerRating, newWinnerRating, oldLoserRating, newLoserRating, err := sys.RecordMatch(winner, loser, score)
+	if err != nil {
+		writeJSON(ctx, 404, H{"error": err.Error()})
+		return
+	}
+	sys.RecalculateRanks()
+
+	writeJSON(ctx, 200, H{
+		"winner": H{"username": winner, "old_rating": oldWinnerRating, "new_rating": newWinnerRating},
+		"loser":  H{"username": loser, "old_rating": oldLoserRating, "new_rating": newLoserRating},
+	})
+}
+
+// handleAdminSnapshot force-flushes the current leaderboard state to the store.
+func handleAdminSnapshot(ctx *fasthttp.RequestCtx) {
+	if err := sys.FlushToStore(); err != nil {
+		writeJSON(ctx, 500, H{"error": err.Error()})
+		return
+	}
+	writeJSON(ctx, 200, H{"status": "ok"})
+}
+
+func main() {
+	rand.Seed(time.Now().UnixNano())
+
+	store, err := storage.New()
+	if err != nil {
+		fmt.Printf("❌ failed to initialize storage: %v\n", err)
+		os.Exit(1)
+	}
+	sys.Store = store
+
+	rehydrated, err := Rehydrate()
+	if err != nil {
+		fmt.Printf("❌ failed to rehydrate from store: %v\n", err)
+		os.Exit(1)
+	}
+	if !rehydrated {
+		SeedUsers()
+	}
+
+	sys.RecalculateRanks()
+	if os.Getenv("SEED_MODE") == "1" {
+		StartScoreUpdates()
+	}
+	StartSnapshotTicker()
+	StartHeartbeatTicker()
+
+	r := router.New()
+	r.GET("/leaderboard", handleLeaderboard)
+	r.GET("/leaderboard/around/:username", handleLeaderboardAround)
+	r.GET("/search", handleSearch)
+	r.GET("/stream", handleStream)
+	r.GET("/ws", handleWS)
+	r.POST("/users", handleRegister)
+	r.POST("/matches", requireAuth(handleMatches))
+	r.POST("/admin/snapshot", handleAdminSnapshot)
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080" // fallback for local run
+	}
+
+	fmt.Printf("🚀 Server running on port %s\n", port)
+	if err := fasthttp.ListenAndServe(":"+port, withCORS(r.Handler)); err != nil {
+		fmt.Printf("❌ server error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// BenchmarkMixedReadWrite exercises the lock-free read path against
+// concurrent writers at roughly a 90% read / 10% write split, the mix
+// the double-buffered snapshot swap is meant to optimize for.
+func BenchmarkMixedReadWrite(b *testing.B) {
+	for i := 0; i < 2000; i++ {
+		sys.setRating(randUsername(i), rand.Intn(4901)+100)
+	}
+	sys.RecalculateRanks()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		rng := rand.New(rand.NewSource(1))
+		i := 0
+		for pb.Next() {
+			if rng.Intn(10) == 0 {
+				sys.setRating(randUsername(rng.Intn(2000)), rng.Intn(4901)+100)
+				if i%50 == 0 {
+					sys.RecalculateRanks()
+				}
+			} else {
+				_ = sys.View().users
+			}
+			i++
+		}
+	})
+}
+
+func randUsername(i int) string {
+	names := []string{"rahul", "arjun", "priya", "vikram", "anisha", "rohan", "sara", "kabir"}
+	return names[i%len(names)] + "_" + strconv.Itoa(i)
+}
@@ -0,0 +1,214 @@
+// Package ranktree provides a size-augmented treap (an order-statistic
+// tree) keyed by (Rating desc, Username asc). It replaces a full re-sort
+// on every mutation with O(log N) insert/delete and O(log N) rank
+// lookups, and O(log N + K) range scans.
+package ranktree
+
+import "math/rand"
+
+// Item is a single leaderboard entry tracked by the tree.
+type Item struct {
+	Username string
+	Rating   int
+}
+
+func less(a, b Item) bool {
+	if a.Rating != b.Rating {
+		return a.Rating > b.Rating
+	}
+	return a.Username < b.Username
+}
+
+type node struct {
+	item        Item
+	priority    int32
+	left, right *node
+	size        int // size of the subtree rooted here, including itself
+}
+
+func size(n *node) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func update(n *node) {
+	n.size = 1 + size(n.left) + size(n.right)
+}
+
+func rotateRight(n *node) *node {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	update(n)
+	update(l)
+	return l
+}
+
+func rotateLeft(n *node) *node {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	update(n)
+	update(r)
+	return r
+}
+
+// Tree is an order-statistic treap. The zero value is not usable; create
+// one with New.
+type Tree struct {
+	root *node
+}
+
+// New returns an empty tree.
+func New() *Tree {
+	return &Tree{}
+}
+
+// Len returns the number of items currently in the tree.
+func (t *Tree) Len() int {
+	return size(t.root)
+}
+
+// Insert adds item to the tree in O(log N).
+func (t *Tree) Insert(item Item) {
+	t.root = insert(t.root, item, rand.Int31())
+}
+
+func insert(n *node, item Item, priority int32) *node {
+	if n == nil {
+		return &node{item: item, priority: priority, size: 1}
+	}
+	if less(item, n.item) {
+		n.left = insert(n.left, item, priority)
+		if n.left.priority > n.priority {
+			n = rotateRight(n)
+		}
+	} else {
+		n.right = insert(n.right, item, priority)
+		if n.right.priority > n.priority {
+			n = rotateLeft(n)
+		}
+	}
+	update(n)
+	return n
+}
+
+// Delete removes item (matched by Username and Rating) from the tree in
+// O(log N). It is a no-op if the item isn't present.
+func (t *Tree) Delete(item Item) {
+	t.root = deleteNode(t.root, item)
+}
+
+func deleteNode(n *node, item Item) *node {
+	if n == nil {
+		return nil
+	}
+	switch {
+	case less(item, n.item):
+		n.left = deleteNode(n.left, item)
+	case less(n.item, item):
+		n.right = deleteNode(n.right, item)
+	default:
+		if n.left == nil {
+			return n.right
+		}
+		if n.right == nil {
+			return n.left
+		}
+		if n.left.priority > n.right.priority {
+			n = rotateRight(n)
+			n.right = deleteNode(n.right, item)
+		} else {
+			n = rotateLeft(n)
+			n.left = deleteNode(n.left, item)
+		}
+	}
+	update(n)
+	return n
+}
+
+// RankOf returns the tie-aware, 1-based rank of a given rating: 1 plus
+// the number of items in the tree with a strictly greater rating. Items
+// sharing a rating all get the same rank.
+func (t *Tree) RankOf(rating int) int {
+	virtual := Item{Rating: rating, Username: ""}
+	return countLess(t.root, virtual) + 1
+}
+
+// countLess returns the number of items that sort strictly before key.
+func countLess(n *node, key Item) int {
+	if n == nil {
+		return 0
+	}
+	if less(key, n.item) {
+		return countLess(n.left, key)
+	}
+	if less(n.item, key) {
+		return size(n.left) + 1 + countLess(n.right, key)
+	}
+	return size(n.left)
+}
+
+// Range returns up to count items starting at the 1-based fromRank, in
+// tree order, in O(log N + count) by descending only the subtrees that
+// overlap the requested window.
+func (t *Tree) Range(fromRank, count int) []Item {
+	if fromRank < 1 {
+		fromRank = 1
+	}
+	k := fromRank - 1
+	if count <= 0 || k >= size(t.root) {
+		return nil
+	}
+
+	out := make([]Item, 0, count)
+	remaining := count
+	collect(t.root, k, &remaining, &out)
+	return out
+}
+
+func collect(n *node, k int, remaining *int, out *[]Item) {
+	if n == nil || *remaining <= 0 {
+		return
+	}
+
+	l := size(n.left)
+	switch {
+	case k < l:
+		collect(n.left, k, remaining, out)
+		if *remaining <= 0 {
+			return
+		}
+		*out = append(*out, n.item)
+		*remaining--
+		collect(n.right, 0, remaining, out)
+	case k == l:
+		*out = append(*out, n.item)
+		*remaining--
+		if *remaining <= 0 {
+			return
+		}
+		collect(n.right, 0, remaining, out)
+	default:
+		collect(n.right, k-l-1, remaining, out)
+	}
+}
+
+// InOrder returns every item in tree order (sorted desc by rating, then
+// asc by username), for callers that need the full materialized list.
+func (t *Tree) InOrder() []Item {
+	out := make([]Item, 0, size(t.root))
+	var walk func(n *node)
+	walk = func(n *node) {
+		if n == nil {
+			return
+		}
+		walk(n.left)
+		out = append(out, n.item)
+		walk(n.right)
+	}
+	walk(t.root)
+	return out
+}
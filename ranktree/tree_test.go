@@ -0,0 +1,54 @@
+package ranktree
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRankOfTiesShareRank(t *testing.T) {
+	tree := New()
+	tree.Insert(Item{Username: "a", Rating: 100})
+	tree.Insert(Item{Username: "b", Rating: 200})
+	tree.Insert(Item{Username: "c", Rating: 200})
+	tree.Insert(Item{Username: "d", Rating: 300})
+
+	cases := map[int]int{300: 1, 200: 2, 100: 4}
+	for rating, want := range cases {
+		if got := tree.RankOf(rating); got != want {
+			t.Errorf("RankOf(%d) = %d, want %d", rating, got, want)
+		}
+	}
+}
+
+func TestRangeMatchesInOrder(t *testing.T) {
+	tree := New()
+	for i := 0; i < 50; i++ {
+		tree.Insert(Item{Username: fmt.Sprintf("u%d", i), Rating: i})
+	}
+
+	all := tree.InOrder()
+	got := tree.Range(10, 5)
+	want := all[9:14]
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Range(10, 5)[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDeleteThenInsertUpdatesRank(t *testing.T) {
+	tree := New()
+	tree.Insert(Item{Username: "a", Rating: 100})
+	tree.Insert(Item{Username: "b", Rating: 200})
+
+	tree.Delete(Item{Username: "a", Rating: 100})
+	tree.Insert(Item{Username: "a", Rating: 300})
+
+	if got := tree.RankOf(300); got != 1 {
+		t.Errorf("RankOf(300) after re-insert = %d, want 1", got)
+	}
+	if got := tree.Len(); got != 2 {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+)
+
+var ratingsBucket = []byte("ratings")
+
+// BoltStore persists ratings to a single embedded BoltDB file. It is the
+// recommended single-node backend: no extra process to run, durable
+// across restarts via bbolt's own WAL-backed B+tree.
+type BoltStore struct {
+	db *bbolt.DB
+}
+
+// NewBoltStore opens (creating if necessary) the BoltDB file at path.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("storage: open bolt db: %w", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(ratingsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("storage: create bucket: %w", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Load() ([]Record, error) {
+	var recs []Record
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(ratingsBucket).ForEach(func(k, v []byte) error {
+			recs = append(recs, Record{Username: string(k), Rating: int(int32(binary.BigEndian.Uint32(v)))})
+			return nil
+		})
+	})
+	return recs, err
+}
+
+func (s *BoltStore) Save(rec Record) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(ratingsBucket).Put([]byte(rec.Username), encodeRating(rec.Rating))
+	})
+}
+
+func (s *BoltStore) SaveAll(recs []Record) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(ratingsBucket)
+		for _, rec := range recs {
+			if err := b.Put([]byte(rec.Username), encodeRating(rec.Rating)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) Close() error { return s.db.Close() }
+
+func encodeRating(rating int) []byte {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, uint32(int32(rating)))
+	return buf
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+)
+
+// New builds a Store from the STORE env var (memory|bolt|redis|postgres),
+// defaulting to MemoryStore when unset so local dev needs no setup.
+func New() (Store, error) {
+	switch kind := os.Getenv("STORE"); kind {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "bolt":
+		return NewBoltStore(envOr("BOLT_PATH", "leaderboard.db"))
+	case "redis":
+		return NewRedisStore(envOr("REDIS_ADDR", "localhost:6379"))
+	case "postgres":
+		dsn := os.Getenv("DATABASE_URL")
+		if dsn == "" {
+			return nil, fmt.Errorf("storage: DATABASE_URL is required when STORE=postgres")
+		}
+		return NewPostgresStore(dsn)
+	default:
+		return nil, fmt.Errorf("storage: unknown STORE %q", kind)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
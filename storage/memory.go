@@ -0,0 +1,47 @@
+package storage
+
+import "sync"
+
+// MemoryStore is the zero-config default: it satisfies the Store
+// interface but keeps everything in a map, so a restart still loses all
+// data. Useful for local dev and the STORE=memory / unset case.
+type MemoryStore struct {
+	mu   sync.Mutex
+	data map[string]int
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: make(map[string]int)}
+}
+
+func (s *MemoryStore) Load() ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recs := make([]Record, 0, len(s.data))
+	for username, rating := range s.data {
+		recs = append(recs, Record{Username: username, Rating: rating})
+	}
+	return recs, nil
+}
+
+func (s *MemoryStore) Save(rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[rec.Username] = rec.Rating
+	return nil
+}
+
+func (s *MemoryStore) SaveAll(recs []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, rec := range recs {
+		s.data[rec.Username] = rec.Rating
+	}
+	return nil
+}
+
+func (s *MemoryStore) Close() error { return nil }
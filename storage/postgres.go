@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/lib/pq"
+)
+
+// PostgresStore persists ratings in a single `users` table. It is the
+// recommended networked backend when the leaderboard needs to join
+// against other relational data (e.g. auth, match history).
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore opens a connection pool against dsn and ensures the
+// backing table exists.
+func NewPostgresStore(dsn string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("storage: open postgres: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("storage: ping postgres: %w", err)
+	}
+
+	const schema = `
+		CREATE TABLE IF NOT EXISTS users (
+			username TEXT PRIMARY KEY,
+			rating   INTEGER NOT NULL
+		)`
+	if _, err := db.Exec(schema); err != nil {
+		return nil, fmt.Errorf("storage: create users table: %w", err)
+	}
+
+	return &PostgresStore{db: db}, nil
+}
+
+func (s *PostgresStore) Load() ([]Record, error) {
+	rows, err := s.db.Query(`SELECT username, rating FROM users`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: select users: %w", err)
+	}
+	defer rows.Close()
+
+	var recs []Record
+	for rows.Next() {
+		var rec Record
+		if err := rows.Scan(&rec.Username, &rec.Rating); err != nil {
+			return nil, fmt.Errorf("storage: scan user row: %w", err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs, rows.Err()
+}
+
+func (s *PostgresStore) Save(rec Record) error {
+	const upsert = `
+		INSERT INTO users (username, rating) VALUES ($1, $2)
+		ON CONFLICT (username) DO UPDATE SET rating = EXCLUDED.rating`
+	_, err := s.db.Exec(upsert, rec.Username, rec.Rating)
+	return err
+}
+
+func (s *PostgresStore) SaveAll(recs []Record) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("storage: begin tx: %w", err)
+	}
+
+	const upsert = `
+		INSERT INTO users (username, rating) VALUES ($1, $2)
+		ON CONFLICT (username) DO UPDATE SET rating = EXCLUDED.rating`
+	for _, rec := range recs {
+		if _, err := tx.Exec(upsert, rec.Username, rec.Rating); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("storage: upsert user: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *PostgresStore) Close() error { return s.db.Close() }
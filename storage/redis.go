@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisHashKey = "leaderboard:ratings"
+
+// RedisStore persists ratings in a single Redis hash (username -> rating).
+// It is the recommended networked backend when multiple API instances
+// share one leaderboard.
+type RedisStore struct {
+	client *redis.Client
+	ctx    context.Context
+}
+
+// NewRedisStore connects to the Redis instance at addr.
+func NewRedisStore(addr string) (*RedisStore, error) {
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	ctx := context.Background()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("storage: connect to redis: %w", err)
+	}
+
+	return &RedisStore{client: client, ctx: ctx}, nil
+}
+
+func (s *RedisStore) Load() ([]Record, error) {
+	all, err := s.client.HGetAll(s.ctx, redisHashKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("storage: hgetall: %w", err)
+	}
+
+	recs := make([]Record, 0, len(all))
+	for username, ratingStr := range all {
+		rating, err := strconv.Atoi(ratingStr)
+		if err != nil {
+			continue
+		}
+		recs = append(recs, Record{Username: username, Rating: rating})
+	}
+	return recs, nil
+}
+
+func (s *RedisStore) Save(rec Record) error {
+	return s.client.HSet(s.ctx, redisHashKey, rec.Username, rec.Rating).Err()
+}
+
+func (s *RedisStore) SaveAll(recs []Record) error {
+	if len(recs) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]interface{}, len(recs))
+	for _, rec := range recs {
+		fields[rec.Username] = rec.Rating
+	}
+	return s.client.HSet(s.ctx, redisHashKey, fields).Err()
+}
+
+func (s *RedisStore) Close() error { return s.client.Close() }
@@ -0,0 +1,28 @@
+// Package storage provides pluggable persistence for the leaderboard so
+// ratings survive a restart instead of living only in process memory.
+package storage
+
+// Record is the persisted representation of a single user's state.
+type Record struct {
+	Username string
+	Rating   int
+}
+
+// Store is the persistence backend for the leaderboard. Implementations
+// must be safe for concurrent use.
+type Store interface {
+	// Load returns every persisted record, used to rehydrate the
+	// in-memory ratings on boot.
+	Load() ([]Record, error)
+
+	// Save upserts a single user's rating. Called on every point-write
+	// so a crash loses at most the in-flight request.
+	Save(rec Record) error
+
+	// SaveAll persists the full current state in one shot. Used by the
+	// periodic snapshot ticker and the /admin/snapshot endpoint.
+	SaveAll(recs []Record) error
+
+	// Close releases any underlying connections or file handles.
+	Close() error
+}
@@ -0,0 +1,175 @@
+// Package trie provides an in-memory prefix trie over usernames, giving
+// O(P + K) prefix lookups and bounded fuzzy matching for typeahead
+// search instead of an O(N) substring scan.
+package trie
+
+import "strings"
+
+type node struct {
+	children map[byte]*node
+	isLeaf   bool
+	username string // original-case username, set when isLeaf
+}
+
+func newNode() *node {
+	return &node{children: make(map[byte]*node)}
+}
+
+// Trie is a case-folded prefix trie over usernames.
+type Trie struct {
+	root *node
+}
+
+// New returns an empty Trie.
+func New() *Trie {
+	return &Trie{root: newNode()}
+}
+
+// Insert adds username to the trie in O(len(username)).
+func (t *Trie) Insert(username string) {
+	key := strings.ToLower(username)
+	n := t.root
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		child, ok := n.children[c]
+		if !ok {
+			child = newNode()
+			n.children[c] = child
+		}
+		n = child
+	}
+	n.isLeaf = true
+	n.username = username
+}
+
+// Delete removes username from the trie, used when a username changes.
+func (t *Trie) Delete(username string) {
+	deleteRec(t.root, strings.ToLower(username), 0)
+}
+
+func deleteRec(n *node, key string, depth int) bool {
+	if depth == len(key) {
+		if !n.isLeaf {
+			return false
+		}
+		n.isLeaf = false
+		n.username = ""
+		return len(n.children) == 0
+	}
+
+	child, ok := n.children[key[depth]]
+	if !ok {
+		return false
+	}
+	if deleteRec(child, key, depth+1) {
+		delete(n.children, key[depth])
+	}
+	return !n.isLeaf && len(n.children) == 0
+}
+
+// PrefixSearch returns every username whose lowercased form starts with
+// prefix, in O(P + K): O(P) to walk down to the prefix's node, O(K) to
+// collect the K matches beneath it.
+func (t *Trie) PrefixSearch(prefix string) []string {
+	key := strings.ToLower(prefix)
+	n := t.root
+	for i := 0; i < len(key); i++ {
+		child, ok := n.children[key[i]]
+		if !ok {
+			return nil
+		}
+		n = child
+	}
+
+	var results []string
+	collect(n, &results)
+	return results
+}
+
+func collect(n *node, out *[]string) {
+	if n.isLeaf {
+		*out = append(*out, n.username)
+	}
+	for _, child := range n.children {
+		collect(child, out)
+	}
+}
+
+// FuzzySearch returns every username whose prefix is within maxDist edit
+// distance of query. Usernames are "name_<number>", so matching the full
+// string (as a naive Levenshtein trie search would) almost never succeeds
+// for a short typeahead query; instead this matches query against a
+// bounded prefix of each candidate (length up to len(query)+maxDist) and,
+// once that prefix matches, includes every username beneath it regardless
+// of what follows. Prefix acceptance is only considered once depth has
+// reached len(query): at a shallower depth the distance is dominated by
+// deleting the query's remaining unconsumed characters rather than by any
+// real match against the candidate, which would accept almost anything
+// for a short query. A complete username shorter than that is still
+// returned when its whole string is within maxDist of query. It walks the trie maintaining a Levenshtein DP row per
+// call and prunes any subtree whose best-case distance already exceeds
+// maxDist, so it only pays for P + (matches within range) rather than
+// scanning every username.
+func (t *Trie) FuzzySearch(query string, maxDist int) []string {
+	key := strings.ToLower(query)
+	row := make([]int, len(key)+1)
+	for i := range row {
+		row[i] = i
+	}
+	maxPrefixLen := len(key) + maxDist
+
+	var results []string
+	for c, child := range t.root.children {
+		fuzzyWalk(child, c, key, row, maxDist, 1, maxPrefixLen, &results)
+	}
+	return results
+}
+
+func fuzzyWalk(n *node, c byte, key string, prevRow []int, maxDist, depth, maxPrefixLen int, out *[]string) {
+	row := make([]int, len(prevRow))
+	row[0] = prevRow[0] + 1
+	best := row[0]
+
+	for i := 1; i < len(row); i++ {
+		cost := 1
+		if key[i-1] == c {
+			cost = 0
+		}
+		row[i] = min3(row[i-1]+1, prevRow[i]+1, prevRow[i-1]+cost)
+		if row[i] < best {
+			best = row[i]
+		}
+	}
+	dist := row[len(row)-1]
+
+	if n.isLeaf && dist <= maxDist {
+		*out = append(*out, n.username)
+	}
+	if depth >= len(key) && dist <= maxDist {
+		// The prefix walked so far already covers the whole query within
+		// budget, so every username beneath it extends an already-matching
+		// prefix - collect them without re-deriving distance for trailing
+		// characters the user hasn't typed yet.
+		for _, child := range n.children {
+			collect(child, out)
+		}
+		return
+	}
+	if best > maxDist || depth >= maxPrefixLen {
+		return // every username in this subtree is already too far
+	}
+
+	for cc, child := range n.children {
+		fuzzyWalk(child, cc, key, row, maxDist, depth+1, maxPrefixLen, out)
+	}
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,90 @@
+package trie
+
+import (
+	"sort"
+	"testing"
+)
+
+func insertAll(t *Trie, usernames ...string) {
+	for _, u := range usernames {
+		t.Insert(u)
+	}
+}
+
+func TestPrefixSearch(t *testing.T) {
+	tr := New()
+	insertAll(tr, "arjun_1", "arjun_2", "arjun_nitpick", "priya_1")
+
+	got := tr.PrefixSearch("arjun")
+	sort.Strings(got)
+	want := []string{"arjun_1", "arjun_2", "arjun_nitpick"}
+
+	if len(got) != len(want) {
+		t.Fatalf("PrefixSearch(arjun) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("PrefixSearch(arjun)[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFuzzySearchWithinEditDistance(t *testing.T) {
+	tr := New()
+	insertAll(tr, "arjun_1234", "priya_9", "vikram_2")
+
+	got := tr.FuzzySearch("arjnu", 2)
+
+	found := false
+	for _, u := range got {
+		if u == "arjun_1234" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("FuzzySearch(arjnu, 2) = %v, want it to include arjun_1234", got)
+	}
+}
+
+// TestFuzzySearchExcludesUnrelatedPrefixes guards against accepting a
+// match before depth has caught up with len(query): at a shallow depth
+// the distance is dominated by deleting the query's own unconsumed
+// characters rather than by any real overlap with the candidate, which
+// used to make a short query match almost every username in the trie.
+func TestFuzzySearchExcludesUnrelatedPrefixes(t *testing.T) {
+	tr := New()
+	insertAll(tr, "rahul_1234", "priya_9", "vikram_2", "arjun_500", "anisha_8")
+
+	got := tr.FuzzySearch("rah", 2)
+	sort.Strings(got)
+
+	wantPresent := "rahul_1234"
+	foundWanted := false
+	for _, u := range got {
+		if u == wantPresent {
+			foundWanted = true
+		}
+	}
+	if !foundWanted {
+		t.Errorf("FuzzySearch(rah, 2) = %v, want it to include %q", got, wantPresent)
+	}
+
+	for _, unrelated := range []string{"priya_9", "vikram_2", "arjun_500", "anisha_8"} {
+		for _, u := range got {
+			if u == unrelated {
+				t.Errorf("FuzzySearch(rah, 2) = %v, want it to exclude unrelated %q", got, unrelated)
+			}
+		}
+	}
+}
+
+func TestDeleteRemovesUsername(t *testing.T) {
+	tr := New()
+	insertAll(tr, "rahul_1")
+
+	tr.Delete("rahul_1")
+
+	if got := tr.PrefixSearch("rahul"); len(got) != 0 {
+		t.Errorf("PrefixSearch(rahul) after delete = %v, want empty", got)
+	}
+}